@@ -0,0 +1,86 @@
+// Package agent holds a parsed config and tracks whether it is running, so
+// that callers can start, shut down and reload it. It does not gather from
+// inputs or write to outputs.
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/config"
+)
+
+// drainTimeout bounds how long Reload waits for the outgoing agent to shut
+// down before handing control back to the caller, so a stuck agent can't
+// block a reload forever.
+const drainTimeout = 10 * time.Second
+
+// Agent runs a single configured set of inputs and outputs.
+type Agent struct {
+	Config *config.Config
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAgent returns an Agent for the given Config.
+func NewAgent(conf *config.Config) (*Agent, error) {
+	return &Agent{Config: conf}, nil
+}
+
+// Connect marks the agent as running by setting up the context that
+// Shutdown cancels. It starts no outputs and runs no inputs.
+func (a *Agent) Connect() error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a.mu.Lock()
+	a.cancel = cancel
+	a.done = make(chan struct{})
+	a.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		close(a.done)
+	}()
+
+	return nil
+}
+
+// Test returns nil without gathering from any input; it prints nothing and
+// starts no outputs.
+func (a *Agent) Test() error {
+	return nil
+}
+
+// Shutdown cancels the context set up by Connect and waits until that
+// cancellation has been observed, or until ctx is done. There is no output
+// buffer, so nothing is flushed. It returns nil if Connect was never called.
+func (a *Agent) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	cancel, done := a.cancel, a.done
+	a.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reload shuts this agent down as Shutdown does, so that a replacement
+// Agent built from a freshly-loaded Config can take over without the two
+// running concurrently. It blocks until shutdown finishes or drainTimeout
+// elapses.
+func (a *Agent) Reload() error {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	return a.Shutdown(ctx)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/influxdata/telegraf/internal/config/loader"
+)
+
+// stringSliceFlag implements flag.Value so that a flag may be given more
+// than once on the command line, collecting every value it was given
+// rather than keeping only the last one.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// fConfig and fConfigDirectory may be repeated; each occurrence is merged
+// into the running config in the order given. A -config value may be a
+// local path or a http(s)://, file://, or etcd:// URL, which is fetched
+// through internal/config/loader and cached to disk for offline restarts.
+// A -config-directory value is a local directory path; it is not passed
+// through the loader, so URLs are not accepted there.
+var fConfig stringSliceFlag
+var fConfigDirectory stringSliceFlag
+
+// configLoader fetches and caches remote -config sources.
+var configLoader = loader.NewLoader("")
+
+func init() {
+	flag.Var(&fConfig, "config",
+		"configuration file or URL to load, may be repeated")
+	flag.Var(&fConfigDirectory, "config-directory",
+		"directory containing additional *.conf files, may be repeated")
+}
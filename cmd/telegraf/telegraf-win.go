@@ -1,18 +1,23 @@
 // +build windows
+
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/chai2010/winsvc"
+	"golang.org/x/sys/windows/svc"
 
 	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/logger"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 )
@@ -22,9 +27,6 @@ var fDebug = flag.Bool("debug", false,
 var fQuiet = flag.Bool("quiet", false,
 	"run in quiet mode")
 var fTest = flag.Bool("test", false, "gather metrics, print them out, and exit")
-var fConfig = flag.String("config", "", "configuration file to load")
-var fConfigDirectory = flag.String("config-directory", "",
-	"directory containing additional *.conf files")
 var fVersion = flag.Bool("version", false, "display the version")
 var fSampleConfig = flag.Bool("sample-config", false,
 	"print out full sample configuration")
@@ -55,6 +57,30 @@ var Version string
 
 var appPath string
 
+// shutdownTimeout bounds how long runOnce, once stop() has closed stopCh,
+// waits for the running agent's Shutdown before returning.
+const shutdownTimeout = 10 * time.Second
+
+// reloadCh is pushed to when the running config should be re-read without
+// stopping the service, and stopCh is closed when the service should shut
+// down for good. Both are consumed by the blocking select at the end of
+// runOnce, which is what lets a paramchange control code actually reach a
+// listener instead of arriving after start() has already returned.
+var (
+	reloadCh = make(chan struct{}, 1)
+	stopCh   = make(chan struct{})
+)
+
+// requestReload signals the running service to re-read its configuration on
+// the next iteration of the start() loop, without tearing down the process.
+func requestReload() {
+	select {
+	case reloadCh <- struct{}{}:
+	default:
+		// a reload is already pending
+	}
+}
+
 const usage = `Telegraf, The plugin-driven server agent for collecting and reporting metrics.
 
 Usage:
@@ -116,6 +142,9 @@ func main() {
 		if err := winsvc.InstallService(appPath, *fServiceName, *fServiceDesc); err != nil {
 			log.Fatalf("installService(%s, %s): %v\n", *fServiceName, *fServiceDesc, err)
 		}
+		if err := logger.RegisterEventSource(*fServiceName); err != nil {
+			log.Fatalf("registerEventSource(%s): %v\n", *fServiceName, err)
+		}
 		fmt.Printf("Done\n")
 		return
 	}
@@ -125,6 +154,9 @@ func main() {
 		if err := winsvc.RemoveService(*fServiceName); err != nil {
 			log.Fatalln("removeService:", err)
 		}
+		if err := logger.DeregisterEventSource(*fServiceName); err != nil {
+			log.Fatalln("deregisterEventSource:", err)
+		}
 		fmt.Printf("Done\n")
 		return
 	}
@@ -150,7 +182,13 @@ func main() {
 	// run as service
 	if !winsvc.InServiceMode() {
 		log.Println("main:", "runService")
-		if err := winsvc.RunAsService(*fServiceName, start, stop, false); err != nil {
+		// chai2010/winsvc's RunAsService only dispatches start/stop, with no
+		// way to observe the SCM's other control codes, so the service is
+		// run directly against golang.org/x/sys/windows/svc instead: that
+		// lets telegrafService.Execute accept svc.ParamChange, which is what
+		// "net start <service> /paramchange"-style reload requests (and
+		// Windows' own config-reload tooling) send.
+		if err := svc.Run(*fServiceName, &telegrafService{}); err != nil {
 			log.Fatalf("svc.Run: %v\n", err)
 		}
 		return
@@ -159,145 +197,214 @@ func main() {
 	start()
 }
 
-func start() {
-	reload := make(chan bool, 1)
-	reload <- true
-	for <-reload {
-		reload <- false
-		flag.Usage = usageExit
-		flag.Parse()
-
-		if flag.NFlag() == 0 {
-			usageExit()
+// telegrafService adapts start/stop/requestReload to the
+// golang.org/x/sys/windows/svc.Handler interface so the running service can
+// accept a paramchange control code as a config reload request, in addition
+// to the usual stop/shutdown.
+type telegrafService struct{}
+
+func (s *telegrafService) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptParamChange
+
+	done := make(chan struct{})
+	changes <- svc.Status{State: svc.StartPending}
+	go func() {
+		start()
+		close(done)
+	}()
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for c := range r {
+		switch c.Cmd {
+		case svc.Interrogate:
+			changes <- c.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			changes <- svc.Status{State: svc.StopPending}
+			stop()
+			<-done
+			changes <- svc.Status{State: svc.Stopped}
+			return false, 0
+		case svc.ParamChange:
+			log.Println("Reloading Telegraf config")
+			requestReload()
+			changes <- c.CurrentStatus
 		}
+	}
 
-		var inputFilters []string
-		if *fInputFiltersLegacy != "" {
-			inputFilter := strings.TrimSpace(*fInputFiltersLegacy)
-			inputFilters = strings.Split(":"+inputFilter+":", ":")
-		}
-		if *fInputFilters != "" {
-			inputFilter := strings.TrimSpace(*fInputFilters)
-			inputFilters = strings.Split(":"+inputFilter+":", ":")
-		}
+	return false, 0
+}
 
-		var outputFilters []string
-		if *fOutputFiltersLegacy != "" {
-			outputFilter := strings.TrimSpace(*fOutputFiltersLegacy)
-			outputFilters = strings.Split(":"+outputFilter+":", ":")
-		}
-		if *fOutputFilters != "" {
-			outputFilter := strings.TrimSpace(*fOutputFilters)
-			outputFilters = strings.Split(":"+outputFilter+":", ":")
-		}
+// start runs the agent, re-entering runOnce each time a paramchange control
+// code requests a config reload, until a stop/shutdown control code is
+// received.
+func start() {
+	for runOnce() {
+	}
+}
 
-		if *fVersion {
-			v := fmt.Sprintf("Telegraf - Version %s", Version)
-			fmt.Println(v)
-			return
-		}
+func runOnce() bool {
+	flag.Usage = usageExit
+	flag.Parse()
 
-		if *fSampleConfig {
-			config.PrintSampleConfig(inputFilters, outputFilters)
-			return
-		}
+	if flag.NFlag() == 0 {
+		usageExit()
+	}
 
-		if *fUsage != "" {
-			if err := config.PrintInputConfig(*fUsage); err != nil {
-				if err2 := config.PrintOutputConfig(*fUsage); err2 != nil {
-					log.Fatalf("%s and %s", err, err2)
-				}
-			}
-			return
-		}
+	var inputFilters []string
+	if *fInputFiltersLegacy != "" {
+		inputFilter := strings.TrimSpace(*fInputFiltersLegacy)
+		inputFilters = strings.Split(":"+inputFilter+":", ":")
+	}
+	if *fInputFilters != "" {
+		inputFilter := strings.TrimSpace(*fInputFilters)
+		inputFilters = strings.Split(":"+inputFilter+":", ":")
+	}
 
-		var (
-			c   *config.Config
-			err error
-		)
+	var outputFilters []string
+	if *fOutputFiltersLegacy != "" {
+		outputFilter := strings.TrimSpace(*fOutputFiltersLegacy)
+		outputFilters = strings.Split(":"+outputFilter+":", ":")
+	}
+	if *fOutputFilters != "" {
+		outputFilter := strings.TrimSpace(*fOutputFilters)
+		outputFilters = strings.Split(":"+outputFilter+":", ":")
+	}
 
-		if *fConfig != "" {
-			c = config.NewConfig()
-			c.OutputFilters = outputFilters
-			c.InputFilters = inputFilters
-			err = c.LoadConfig(*fConfig)
-			if err != nil {
-				log.Fatal(err)
+	if *fVersion {
+		v := fmt.Sprintf("Telegraf - Version %s", Version)
+		fmt.Println(v)
+		return false
+	}
+
+	if *fSampleConfig {
+		config.PrintSampleConfig(inputFilters, outputFilters)
+		return false
+	}
+
+	if *fUsage != "" {
+		if err := config.PrintInputConfig(*fUsage); err != nil {
+			if err2 := config.PrintOutputConfig(*fUsage); err2 != nil {
+				log.Fatalf("%s and %s", err, err2)
 			}
-		} else {
-			fmt.Println("Usage: Telegraf")
-			flag.PrintDefaults()
-			return
 		}
+		return false
+	}
 
-		if *fConfigDirectoryLegacy != "" {
-			err = c.LoadDirectory(*fConfigDirectoryLegacy)
+	var (
+		c   *config.Config
+		err error
+	)
+
+	if len(fConfig) > 0 {
+		c = config.NewConfig()
+		c.OutputFilters = outputFilters
+		c.InputFilters = inputFilters
+		for _, source := range fConfig {
+			path, err := configLoader.Fetch(source)
 			if err != nil {
 				log.Fatal(err)
 			}
-		}
-
-		if *fConfigDirectory != "" {
-			err = c.LoadDirectory(*fConfigDirectory)
-			if err != nil {
+			if err := c.LoadConfig(path); err != nil {
 				log.Fatal(err)
 			}
 		}
-		if len(c.Outputs) == 0 {
-			log.Fatalf("Error: no outputs found, did you provide a valid config file?")
-		}
-		if len(c.Inputs) == 0 {
-			log.Fatalf("Error: no inputs found, did you provide a valid config file?")
-		}
+	} else {
+		fmt.Println("Usage: Telegraf")
+		flag.PrintDefaults()
+		return false
+	}
 
-		ag, err := agent.NewAgent(c)
+	if *fConfigDirectoryLegacy != "" {
+		err = c.LoadDirectory(*fConfigDirectoryLegacy)
 		if err != nil {
 			log.Fatal(err)
 		}
+	}
 
-		if *fDebug {
-			ag.Config.Agent.Debug = true
+	for _, dir := range fConfigDirectory {
+		if err := c.LoadDirectory(dir); err != nil {
+			log.Fatal(err)
 		}
+	}
+	if len(c.Outputs) == 0 {
+		log.Fatalf("Error: no outputs found, did you provide a valid config file?")
+	}
+	if len(c.Inputs) == 0 {
+		log.Fatalf("Error: no inputs found, did you provide a valid config file?")
+	}
 
-		if *fQuiet {
-			ag.Config.Agent.Quiet = true
+	if winsvc.InServiceMode() {
+		logtarget := c.Agent.LogTarget
+		if logtarget == "" {
+			logtarget = logger.LogTargetEventlog
 		}
-
-		if *fTest {
-			err = ag.Test()
-			if err != nil {
-				log.Fatal(err)
-			}
-			return
+		if err := logger.SetupLogging(logtarget, c.Agent.Logfile, *fServiceName); err != nil {
+			log.Fatal(err)
 		}
+	}
+
+	ag, err := agent.NewAgent(c)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *fDebug {
+		ag.Config.Agent.Debug = true
+	}
+
+	if *fQuiet {
+		ag.Config.Agent.Quiet = true
+	}
 
-		err = ag.Connect()
+	if *fTest {
+		err = ag.Test()
 		if err != nil {
 			log.Fatal(err)
 		}
+		return false
+	}
 
-		log.Printf("Starting Telegraf (version %s)\n", Version)
-		log.Printf("Loaded outputs: %s", strings.Join(c.OutputNames(), " "))
-		log.Printf("Loaded inputs: %s", strings.Join(c.InputNames(), " "))
-		log.Printf("Tags enabled: %s", c.ListTags())
-
-		if *fPidfile != "" {
-			f, err := os.Create(*fPidfile)
-			if err != nil {
-				log.Fatalf("Unable to create pidfile: %s", err)
-			}
+	err = ag.Connect()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-			fmt.Fprintf(f, "%d\n", os.Getpid())
+	log.Printf("Starting Telegraf (version %s)\n", Version)
+	log.Printf("Loaded outputs: %s", strings.Join(c.OutputNames(), " "))
+	log.Printf("Loaded inputs: %s", strings.Join(c.InputNames(), " "))
+	log.Printf("Tags enabled: %s", c.ListTags())
 
-			f.Close()
+	if *fPidfile != "" {
+		f, err := os.Create(*fPidfile)
+		if err != nil {
+			log.Fatalf("Unable to create pidfile: %s", err)
 		}
 
+		fmt.Fprintf(f, "%d\n", os.Getpid())
+
+		f.Close()
+	}
+
+	select {
+	case <-reloadCh:
+		log.Printf("Reloading Telegraf config")
+		if err := ag.Reload(); err != nil {
+			log.Printf("Error reloading agent: %s", err)
+		}
+		return true
+	case <-stopCh:
+		log.Printf("Stopping Telegraf, flushing outputs")
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := ag.Shutdown(ctx); err != nil {
+			log.Printf("Error during graceful shutdown: %s", err)
+		}
+		return false
 	}
 }
 
 func stop() {
-
-	log.Printf("Hard Shutdown")
+	close(stopCh)
 }
 
 func usageExit() {
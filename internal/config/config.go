@@ -0,0 +1,169 @@
+// Package config parses telegraf's TOML-ish configuration files into a
+// Config ready for agent.NewAgent.
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AgentConfig holds the settings from the [agent] table that apply to the
+// process as a whole rather than to any one input or output.
+type AgentConfig struct {
+	Debug bool
+	Quiet bool
+
+	// LogTarget selects where log output goes when telegraf runs as a
+	// Windows service: "eventlog" (the default there), "file", or
+	// "stderr". The non-windows entrypoint does not read it and logs to
+	// stderr.
+	LogTarget string
+
+	// Logfile is the path log output is appended to when LogTarget is
+	// "file".
+	Logfile string
+}
+
+// PluginConfig is a configured instance of an input or output plugin.
+type PluginConfig struct {
+	Name string
+}
+
+// Config is a parsed telegraf configuration, built up by one or more calls
+// to LoadConfig and LoadDirectory.
+type Config struct {
+	Agent AgentConfig
+
+	OutputFilters []string
+	InputFilters  []string
+
+	Outputs []*PluginConfig
+	Inputs  []*PluginConfig
+
+	tags map[string]string
+}
+
+// NewConfig returns an empty Config ready to be populated via LoadConfig
+// and LoadDirectory.
+func NewConfig() *Config {
+	return &Config{
+		tags: make(map[string]string),
+	}
+}
+
+var tableHeader = regexp.MustCompile(`^\[\[(inputs|outputs)\.([a-zA-Z0-9_]+)\]\]$`)
+var keyValue = regexp.MustCompile(`^([a-zA-Z0-9_]+)\s*=\s*(.+)$`)
+
+// LoadConfig parses the config file at path and merges its [agent] settings
+// and [[inputs.*]]/[[outputs.*]] plugin declarations into c. It may be
+// called more than once on the same Config, in which case later files are
+// additive: new plugins are appended and any [agent] keys they set override
+// earlier ones.
+func (c *Config) LoadConfig(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: could not open %q: %s", path, err)
+	}
+	defer f.Close()
+
+	var section string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if m := tableHeader.FindStringSubmatch(line); m != nil {
+			plugin := &PluginConfig{Name: m[2]}
+			if m[1] == "inputs" {
+				c.Inputs = append(c.Inputs, plugin)
+			} else {
+				c.Outputs = append(c.Outputs, plugin)
+			}
+			section = ""
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+
+		if section != "agent" {
+			continue
+		}
+
+		m := keyValue.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		key := m[1]
+		value := strings.Trim(m[2], `"`)
+
+		switch key {
+		case "logtarget":
+			c.Agent.LogTarget = value
+		case "logfile":
+			c.Agent.Logfile = value
+		case "debug":
+			if b, err := strconv.ParseBool(value); err == nil {
+				c.Agent.Debug = b
+			}
+		case "quiet":
+			if b, err := strconv.ParseBool(value); err == nil {
+				c.Agent.Quiet = b
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// LoadDirectory loads every *.conf file in dir (non-recursively) the same
+// way LoadConfig does.
+func (c *Config) LoadDirectory(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.conf"))
+	if err != nil {
+		return fmt.Errorf("config: could not read directory %q: %s", dir, err)
+	}
+	for _, file := range files {
+		if err := c.LoadConfig(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutputNames returns the configured name of every output plugin.
+func (c *Config) OutputNames() []string {
+	names := make([]string, 0, len(c.Outputs))
+	for _, o := range c.Outputs {
+		names = append(names, o.Name)
+	}
+	return names
+}
+
+// InputNames returns the configured name of every input plugin.
+func (c *Config) InputNames() []string {
+	names := make([]string, 0, len(c.Inputs))
+	for _, i := range c.Inputs {
+		names = append(names, i.Name)
+	}
+	return names
+}
+
+// ListTags returns the global tags configured for this run as a
+// space-separated "key=value" list, for logging at startup.
+func (c *Config) ListTags() string {
+	tags := make([]string, 0, len(c.tags))
+	for k, v := range c.tags {
+		tags = append(tags, fmt.Sprintf("%s=%s", k, v))
+	}
+	return strings.Join(tags, " ")
+}
@@ -0,0 +1,177 @@
+// Package loader resolves a telegraf "-config" source, which may be a local
+// path or a http(s)://, file://, or etcd:// URL, down to a local file path
+// that internal/config.Config can load.
+//
+// Remote sources are cached to disk so that a host can still start from its
+// last known-good config if the remote source is unreachable on a later
+// restart.
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Loader fetches config sources, caching remote ones under CacheDir.
+type Loader struct {
+	CacheDir string
+}
+
+// NewLoader returns a Loader that caches remote sources under cacheDir. An
+// empty cacheDir falls back to DefaultCacheDir().
+func NewLoader(cacheDir string) *Loader {
+	if cacheDir == "" {
+		cacheDir = DefaultCacheDir()
+	}
+	return &Loader{CacheDir: cacheDir}
+}
+
+// DefaultCacheDir returns the directory remote configs are cached in when
+// no explicit cache directory is configured.
+func DefaultCacheDir() string {
+	return filepath.Join(os.TempDir(), "telegraf", "config-cache")
+}
+
+// Fetch resolves source to a local file path. Local paths are returned
+// as-is and file:// URLs are returned as their path, without the scheme;
+// http(s):// and etcd:// sources are downloaded
+// and cached to l.CacheDir, keyed by source so repeated fetches of the same
+// source reuse and refresh the same cache file.
+func (l *Loader) Fetch(source string) (string, error) {
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" || isDriveLetter(u.Scheme) {
+		// Not a URL, or a Windows drive letter (e.g. "C:\...") that
+		// net/url.Parse happily accepts as a single-letter scheme; either
+		// way, treat it as a local path.
+		return source, nil
+	}
+
+	switch u.Scheme {
+	case "file":
+		return u.Path, nil
+	case "http", "https":
+		body, err := fetchHTTP(source)
+		if err != nil {
+			return l.fallbackToCache(source, err)
+		}
+		return l.writeCache(source, body)
+	case "etcd":
+		body, err := fetchEtcd(u)
+		if err != nil {
+			return l.fallbackToCache(source, err)
+		}
+		return l.writeCache(source, body)
+	default:
+		return "", fmt.Errorf("loader: unsupported config source scheme %q", u.Scheme)
+	}
+}
+
+// isDriveLetter reports whether scheme is a single ASCII letter, the shape
+// net/url.Parse reports for the "C" in a Windows path like
+// `C:\Program Files\Telegraf\telegraf.conf` — never a real URL scheme we
+// support.
+func isDriveLetter(scheme string) bool {
+	if len(scheme) != 1 {
+		return false
+	}
+	c := scheme[0]
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// fallbackToCache is used when a remote source could not be reached; it
+// returns the last cached copy of source instead of failing outright, so a
+// host can still restart offline.
+func (l *Loader) fallbackToCache(source string, fetchErr error) (string, error) {
+	cachePath := l.cachePath(source)
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	}
+	return "", fetchErr
+}
+
+func (l *Loader) writeCache(source string, body []byte) (string, error) {
+	if err := os.MkdirAll(l.CacheDir, 0755); err != nil {
+		return "", fmt.Errorf("loader: could not create cache dir %q: %s", l.CacheDir, err)
+	}
+	cachePath := l.cachePath(source)
+	if err := ioutil.WriteFile(cachePath, body, 0644); err != nil {
+		return "", fmt.Errorf("loader: could not cache %q to %q: %s", source, cachePath, err)
+	}
+	return cachePath, nil
+}
+
+func (l *Loader) cachePath(source string) string {
+	return filepath.Join(l.CacheDir, cacheFileName(source))
+}
+
+func cacheFileName(source string) string {
+	h := fnv32a(source)
+	return fmt.Sprintf("%08x.conf", h)
+}
+
+// fnv32a is a tiny, dependency-free FNV-1a hash, good enough to turn a
+// source URL into a stable cache file name.
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
+
+func fetchHTTP(source string) ([]byte, error) {
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("loader: fetching %q: %s", source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("loader: fetching %q: got HTTP %d", source, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("loader: reading %q: %s", source, err)
+	}
+	return body, nil
+}
+
+// etcdKeyResponse mirrors the subset of etcd's v2 HTTP keys API response
+// that we need to pull out a single key's value.
+type etcdKeyResponse struct {
+	Node struct {
+		Value string `json:"value"`
+	} `json:"node"`
+}
+
+// fetchEtcd resolves an "etcd://host:port/key" source against etcd's v2
+// HTTP keys API.
+func fetchEtcd(u *url.URL) ([]byte, error) {
+	endpoint := fmt.Sprintf("http://%s/v2/keys%s", u.Host, u.Path)
+	resp, err := http.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("loader: fetching %q: %s", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("loader: fetching %q: got HTTP %d", endpoint, resp.StatusCode)
+	}
+
+	var kv etcdKeyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&kv); err != nil {
+		return nil, fmt.Errorf("loader: decoding etcd response from %q: %s", endpoint, err)
+	}
+	return []byte(kv.Node.Value), nil
+}
@@ -0,0 +1,121 @@
+package loader
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetchLocalPathPassesThrough(t *testing.T) {
+	l := NewLoader("")
+	path, err := l.Fetch("/etc/telegraf/telegraf.conf")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if path != "/etc/telegraf/telegraf.conf" {
+		t.Errorf("Fetch(local path) = %q, want it unchanged", path)
+	}
+}
+
+func TestFetchFileURLStripsScheme(t *testing.T) {
+	l := NewLoader("")
+	path, err := l.Fetch("file:///etc/telegraf/telegraf.conf")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if path != "/etc/telegraf/telegraf.conf" {
+		t.Errorf("Fetch(file://) = %q, want %q", path, "/etc/telegraf/telegraf.conf")
+	}
+}
+
+func TestFetchHTTPCachesToDisk(t *testing.T) {
+	const body = "[agent]\n  logtarget = \"file\"\n"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "telegraf-loader-test")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := NewLoader(dir)
+	path, err := l.Fetch(srv.URL)
+	if err != nil {
+		t.Fatalf("Fetch(%q): %s", srv.URL, err)
+	}
+
+	if filepath.Dir(path) != dir {
+		t.Errorf("cached path %q not under cache dir %q", path, dir)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%q): %s", path, err)
+	}
+	if string(got) != body {
+		t.Errorf("cached contents = %q, want %q", got, body)
+	}
+}
+
+func TestFetchHTTPFallsBackToCacheWhenUnreachable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-loader-test")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	const source = "http://127.0.0.1:1/telegraf.conf"
+	l := NewLoader(dir)
+
+	cached := l.cachePath(source)
+	if err := ioutil.WriteFile(cached, []byte("cached config"), 0644); err != nil {
+		t.Fatalf("seeding cache file: %s", err)
+	}
+
+	path, err := l.Fetch(source)
+	if err != nil {
+		t.Fatalf("Fetch should fall back to cache, got error: %s", err)
+	}
+	if path != cached {
+		t.Errorf("Fetch() = %q, want cached path %q", path, cached)
+	}
+}
+
+func TestCacheFileNameIsStableAndDistinct(t *testing.T) {
+	a := cacheFileName("http://example.com/telegraf.conf")
+	b := cacheFileName("http://example.com/telegraf.conf")
+	c := cacheFileName("http://example.com/other.conf")
+
+	if a != b {
+		t.Errorf("cacheFileName not stable across calls: %q != %q", a, b)
+	}
+	if a == c {
+		t.Errorf("cacheFileName collided for distinct sources: %q", a)
+	}
+}
+
+func TestFetchWindowsDriveLetterPassesThrough(t *testing.T) {
+	l := NewLoader("")
+	const path = `C:\Program Files\Telegraf\telegraf.conf`
+	got, err := l.Fetch(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got != path {
+		t.Errorf("Fetch(%q) = %q, want it unchanged", path, got)
+	}
+}
+
+func TestFetchUnsupportedScheme(t *testing.T) {
+	l := NewLoader("")
+	if _, err := l.Fetch("etcd2://127.0.0.1:2379/telegraf"); err == nil {
+		t.Fatal("expected an error for an unsupported scheme")
+	}
+}
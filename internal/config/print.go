@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+)
+
+// PrintSampleConfig prints a full sample configuration to stdout, scoped to
+// the given input and output filters (an empty filter prints all plugins).
+//
+// This trimmed-down tree doesn't carry the plugins/inputs and
+// plugins/outputs registries, so there is nothing to enumerate yet; it
+// prints the [agent] table that every config needs.
+func PrintSampleConfig(inputFilters, outputFilters []string) {
+	fmt.Print(`# Telegraf Configuration
+#
+# outputs and inputs are configured below this [agent] table via
+# [[outputs.NAME]] and [[inputs.NAME]] tables.
+
+[agent]
+  ## Default data collection interval for all inputs
+  interval = "10s"
+
+  ## Log target when running as a windows service: "eventlog" (default
+  ## when unset), "file", or "stderr"
+  logtarget = "stderr"
+  ## File to write to when logtarget = "file"
+  logfile = ""
+`)
+}
+
+// PrintInputConfig prints the sample config for the named input plugin. It
+// returns an error if name isn't a known input.
+func PrintInputConfig(name string) error {
+	return fmt.Errorf("Input %s not found", name)
+}
+
+// PrintOutputConfig prints the sample config for the named output plugin.
+// It returns an error if name isn't a known output.
+func PrintOutputConfig(name string) error {
+	return fmt.Errorf("Output %s not found", name)
+}
@@ -0,0 +1,24 @@
+// +build !windows
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+var errEventlogUnsupported = errors.New("eventlog logging is only supported on windows")
+
+func newEventWriter(name string) (io.Writer, error) {
+	return nil, errEventlogUnsupported
+}
+
+// RegisterEventSource always fails on platforms without a Windows Event Log.
+func RegisterEventSource(name string) error {
+	return errEventlogUnsupported
+}
+
+// DeregisterEventSource always fails on platforms without a Windows Event Log.
+func DeregisterEventSource(name string) error {
+	return errEventlogUnsupported
+}
@@ -0,0 +1,19 @@
+// +build !windows
+
+package logger
+
+import "testing"
+
+func TestEventlogUnsupportedOffWindows(t *testing.T) {
+	if _, err := newWriter(LogTargetEventlog, "", "telegraf-test"); err == nil {
+		t.Fatal("expected an error requesting eventlog logging off windows")
+	}
+
+	if err := RegisterEventSource("telegraf-test"); err == nil {
+		t.Fatal("expected RegisterEventSource to fail off windows")
+	}
+
+	if err := DeregisterEventSource("telegraf-test"); err == nil {
+		t.Fatal("expected DeregisterEventSource to fail off windows")
+	}
+}
@@ -0,0 +1,43 @@
+// +build windows
+
+package logger
+
+import (
+	"io"
+
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventWriter adapts the Windows Event Log to an io.Writer so it can be
+// plugged into the standard "log" package.
+type eventWriter struct {
+	log *eventlog.Log
+}
+
+func newEventWriter(name string) (io.Writer, error) {
+	l, err := eventlog.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &eventWriter{log: l}, nil
+}
+
+func (w *eventWriter) Write(p []byte) (int, error) {
+	if err := w.log.Info(1, string(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// RegisterEventSource installs name as a source in the Windows Event Log so
+// that it can later be opened with SetupLogging(LogTargetEventlog, ...). It
+// should be called once, when the service is installed.
+func RegisterEventSource(name string) error {
+	return eventlog.InstallAsEventCreate(name, eventlog.Info|eventlog.Warning|eventlog.Error)
+}
+
+// DeregisterEventSource removes the Event Log source previously installed by
+// RegisterEventSource. It should be called when the service is removed.
+func DeregisterEventSource(name string) error {
+	return eventlog.Remove(name)
+}
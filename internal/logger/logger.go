@@ -0,0 +1,58 @@
+// Package logger sets up Telegraf's global logger so that messages written
+// with the standard "log" package end up somewhere an operator can actually
+// find them, regardless of how telegraf was started.
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Supported values for the [agent] logtarget config option.
+const (
+	LogTargetEventlog = "eventlog"
+	LogTargetFile     = "file"
+	LogTargetStderr   = "stderr"
+)
+
+// SetupLogging configures the destination of the standard logger according
+// to logtarget.
+//
+//   - "eventlog" writes to the Windows Event Log under name. Only supported
+//     on windows, and only after name has been registered as an event
+//     source with RegisterEventSource.
+//   - "file" writes to logfile, appending and creating it if necessary. An
+//     empty logfile falls back to stderr.
+//   - anything else (including "stderr" or "") writes to stderr.
+func SetupLogging(logtarget, logfile, name string) error {
+	w, err := newWriter(logtarget, logfile, name)
+	if err != nil {
+		return err
+	}
+	log.SetOutput(w)
+	return nil
+}
+
+func newWriter(logtarget, logfile, name string) (io.Writer, error) {
+	switch logtarget {
+	case LogTargetEventlog:
+		w, err := newEventWriter(name)
+		if err != nil {
+			return nil, fmt.Errorf("logger: could not open eventlog: %s", err)
+		}
+		return w, nil
+	case LogTargetFile:
+		if logfile == "" {
+			return os.Stderr, nil
+		}
+		f, err := os.OpenFile(logfile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("logger: could not open logfile %q: %s", logfile, err)
+		}
+		return f, nil
+	default:
+		return os.Stderr, nil
+	}
+}
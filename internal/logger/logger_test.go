@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewWriterDefaultsToStderr(t *testing.T) {
+	for _, target := range []string{"", "stderr", "bogus"} {
+		w, err := newWriter(target, "", "telegraf-test")
+		if err != nil {
+			t.Fatalf("newWriter(%q): unexpected error: %s", target, err)
+		}
+		if w != os.Stderr {
+			t.Errorf("newWriter(%q) = %v, want os.Stderr", target, w)
+		}
+	}
+}
+
+func TestNewWriterFileEmptyPathFallsBackToStderr(t *testing.T) {
+	w, err := newWriter(LogTargetFile, "", "telegraf-test")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if w != os.Stderr {
+		t.Errorf("newWriter(file, \"\") = %v, want os.Stderr", w)
+	}
+}
+
+func TestNewWriterFileOpensLogfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf-logger-test")
+	if err != nil {
+		t.Fatalf("TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "telegraf.log")
+	w, err := newWriter(LogTargetFile, path, "telegraf-test")
+	if err != nil {
+		t.Fatalf("newWriter(file, %q): unexpected error: %s", path, err)
+	}
+	if closer, ok := w.(interface{ Close() error }); ok {
+		defer closer.Close()
+	}
+
+	if _, err := w.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%q): %s", path, err)
+	}
+	if string(got) != "hello\n" {
+		t.Errorf("logfile contents = %q, want %q", got, "hello\n")
+	}
+}